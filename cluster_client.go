@@ -0,0 +1,382 @@
+package cbmgr
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how many times ClusterClient.Do retries a request
+// against a sibling node, and how long it backs off between attempts.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseBackoff: 250 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// ClusterClient is a cluster-aware Couchbase client. Unlike Couchbase,
+// which talks to a single node, ClusterClient holds a seed list of node
+// URLs, periodically refreshes the live endpoint list from Nodes(), and
+// retries a failed request against a sibling node before giving up. It is
+// intended for HA operator use, where any single node may be down,
+// rebalancing, or mid-restart.
+type ClusterClient struct {
+	Username string
+	Password string
+
+	mu              sync.RWMutex
+	seeds           []string
+	endpoints       []*Couchbase
+	nextEndpoint    int
+	retryPolicy     RetryPolicy
+	refreshInterval time.Duration
+	lastRefresh     time.Time
+	tls             tlsSettings
+}
+
+// tlsSettings records the TLS configuration applied to a ClusterClient so
+// it can be replayed onto every endpoint - seeds added via AddSeed and
+// nodes discovered by refreshEndpoints - not just the single Couchbase
+// client a setter happened to be called against.
+type tlsSettings struct {
+	skipVerifySet bool
+	skipVerify    bool
+	rootFile      string
+	certFile      string
+	keyFile       string
+}
+
+func (t tlsSettings) apply(node *Couchbase) error {
+	if t.skipVerifySet {
+		node.SetSkipVerify(t.skipVerify)
+	}
+	if t.rootFile != "" {
+		if err := node.SetRootFile(t.rootFile); err != nil {
+			return err
+		}
+	}
+	if t.certFile != "" {
+		if err := node.SetCertFile(t.certFile); err != nil {
+			return err
+		}
+	}
+	if t.keyFile != "" {
+		if err := node.SetKeyFile(t.keyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewClusterClient builds a ClusterClient seeded with the given node URLs.
+// The seed list may be empty; AddSeed can add seeds later, and Do fails
+// while the client has no endpoints.
+func NewClusterClient(username, password string, seeds ...string) (*ClusterClient, error) {
+	cc := &ClusterClient{
+		Username:        username,
+		Password:        password,
+		retryPolicy:     defaultRetryPolicy,
+		refreshInterval: 30 * time.Second,
+	}
+	for _, seed := range seeds {
+		if err := cc.AddSeed(seed); err != nil {
+			return nil, err
+		}
+	}
+	return cc, nil
+}
+
+// AddSeed adds a node URL to the seed list used to bootstrap and
+// replenish the endpoint list. The TLS configuration set via SetSkipVerify,
+// SetRootFile, SetCertFile and SetKeyFile is applied to the new endpoint.
+func (cc *ClusterClient) AddSeed(rawURL string) error {
+	node, err := New(rawURL)
+	if err != nil {
+		return err
+	}
+	node.Username = cc.Username
+	node.Password = cc.Password
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if err := cc.tls.apply(node); err != nil {
+		return err
+	}
+	cc.seeds = append(cc.seeds, rawURL)
+	cc.endpoints = append(cc.endpoints, node)
+	return nil
+}
+
+// SetSkipVerify controls whether endpoints verify the server's TLS
+// certificate, matching Couchbase.SetSkipVerify. It applies to every
+// current endpoint and is replayed onto endpoints added by AddSeed or
+// discovered by a later refresh.
+func (cc *ClusterClient) SetSkipVerify(skip bool) {
+	cc.mu.Lock()
+	cc.tls.skipVerifySet = true
+	cc.tls.skipVerify = skip
+	endpoints := cc.endpoints
+	cc.mu.Unlock()
+
+	for _, node := range endpoints {
+		node.SetSkipVerify(skip)
+	}
+}
+
+// SetRootFile loads a PEM-encoded root CA bundle and uses it to verify
+// the certificate presented by every endpoint, matching
+// Couchbase.SetRootFile.
+func (cc *ClusterClient) SetRootFile(path string) error {
+	cc.mu.Lock()
+	cc.tls.rootFile = path
+	endpoints := cc.endpoints
+	cc.mu.Unlock()
+
+	for _, node := range endpoints {
+		if err := node.SetRootFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCertFile sets the client certificate used for mutual TLS by every
+// endpoint, matching Couchbase.SetCertFile. The certificate is only
+// loaded once a matching key has been set via SetKeyFile.
+func (cc *ClusterClient) SetCertFile(path string) error {
+	cc.mu.Lock()
+	cc.tls.certFile = path
+	endpoints := cc.endpoints
+	cc.mu.Unlock()
+
+	for _, node := range endpoints {
+		if err := node.SetCertFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetKeyFile sets the client key used for mutual TLS by every endpoint,
+// matching Couchbase.SetKeyFile. The key is only loaded once a matching
+// certificate has been set via SetCertFile.
+func (cc *ClusterClient) SetKeyFile(path string) error {
+	cc.mu.Lock()
+	cc.tls.keyFile = path
+	endpoints := cc.endpoints
+	cc.mu.Unlock()
+
+	for _, node := range endpoints {
+		if err := node.SetKeyFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRetryPolicy overrides the retry behaviour used by Do.
+func (cc *ClusterClient) SetRetryPolicy(maxAttempts int, baseBackoff, maxBackoff time.Duration) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.retryPolicy = RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseBackoff: baseBackoff,
+		MaxBackoff:  maxBackoff,
+	}
+}
+
+// SetRefreshInterval overrides how often the endpoint list is refreshed
+// from /pools/default. A zero interval disables automatic refresh.
+func (cc *ClusterClient) SetRefreshInterval(interval time.Duration) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.refreshInterval = interval
+}
+
+func (cc *ClusterClient) snapshot() ([]*Couchbase, RetryPolicy) {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	endpoints := make([]*Couchbase, len(cc.endpoints))
+	copy(endpoints, cc.endpoints)
+	return endpoints, cc.retryPolicy
+}
+
+// Do sends a single REST request, retrying against sibling nodes on
+// connection errors or 5xx responses, and returns the URL of the endpoint
+// that ultimately served the request so callers can log or trace it. A
+// 401 response fails fast, matching Couchbase.Request's existing
+// behaviour of not retrying bad credentials.
+func (cc *ClusterClient) Do(ctx context.Context, method, path string, body []byte, header *http.Header) (resp *http.Response, endpoint string, err error) {
+	cc.maybeRefresh(ctx)
+
+	endpoints, policy := cc.snapshot()
+	if len(endpoints) == 0 {
+		return nil, "", fmt.Errorf("cluster client has no endpoints")
+	}
+
+	start := cc.takeEndpointIndex(len(endpoints))
+
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastEndpoint string
+	for attempt := 0; attempt < attempts; attempt++ {
+		node := endpoints[(start+attempt)%len(endpoints)]
+		// RequestContext stores credentials in node.URL.User; report the
+		// endpoint without them.
+		served := *node.URL
+		served.User = nil
+		lastEndpoint = served.String()
+
+		resp, err = node.RequestContext(ctx, method, path, body, header)
+		if err != nil {
+			// RequestContext returns a CouchbaseError wrapping ErrAuth for
+			// 401s before a *http.Response ever exists, so bad credentials
+			// must be detected on err, not resp.StatusCode.
+			if IsAuth(err) {
+				return nil, lastEndpoint, err
+			}
+		} else if resp.StatusCode < 500 {
+			return resp, lastEndpoint, nil
+		}
+
+		if attempt == attempts-1 {
+			break
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if backoffErr := sleepWithJitter(ctx, backoffDuration(policy, attempt)); backoffErr != nil {
+			return nil, lastEndpoint, backoffErr
+		}
+	}
+
+	return resp, lastEndpoint, err
+}
+
+func (cc *ClusterClient) takeEndpointIndex(n int) int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	idx := cc.nextEndpoint % n
+	cc.nextEndpoint++
+	return idx
+}
+
+func backoffDuration(policy RetryPolicy, attempt int) time.Duration {
+	backoff := policy.BaseBackoff << uint(attempt)
+	if backoff <= 0 || backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff) + 1))
+	return backoff/2 + jitter/2
+}
+
+func sleepWithJitter(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// maybeRefresh refreshes the endpoint list from the first reachable
+// endpoint's Nodes() call, if refreshInterval has elapsed since the last
+// refresh.
+func (cc *ClusterClient) maybeRefresh(ctx context.Context) {
+	cc.mu.RLock()
+	interval := cc.refreshInterval
+	due := interval > 0 && time.Since(cc.lastRefresh) >= interval
+	cc.mu.RUnlock()
+
+	if !due {
+		return
+	}
+
+	_ = cc.refreshEndpoints(ctx)
+}
+
+// refreshEndpoints asks the first endpoint that answers for the current
+// node list and rebuilds the endpoint list from it, preserving the
+// scheme, port and TLS/credential configuration of the seed that served
+// the request. Node.Hostname only carries the plain REST port (e.g.
+// ip:8091), which is wrong for a cluster reached over https on 18091 or a
+// custom port, so the serving endpoint's own port is used instead of
+// Hostname's.
+func (cc *ClusterClient) refreshEndpoints(ctx context.Context) error {
+	endpoints, _ := cc.snapshot()
+
+	var nodes []Node
+	var scheme, port string
+	var err error
+	for _, endpoint := range endpoints {
+		nodes, err = endpoint.NodesContext(ctx)
+		if err == nil {
+			scheme = endpoint.URL.Scheme
+			port = endpoint.URL.Port()
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if port == "" {
+		if scheme == "https" {
+			port = strconv.Itoa(int(defaultHTTPSPort))
+		} else {
+			port = strconv.Itoa(int(defaultHTTPPort))
+		}
+	}
+
+	cc.mu.RLock()
+	tls := cc.tls
+	cc.mu.RUnlock()
+
+	fresh := make([]*Couchbase, 0, len(nodes))
+	for _, n := range nodes {
+		host := n.Hostname
+		if h, _, splitErr := net.SplitHostPort(n.Hostname); splitErr == nil {
+			host = h
+		}
+		u := &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, port)}
+		node := &Couchbase{
+			URL:      u,
+			Username: cc.Username,
+			Password: cc.Password,
+		}
+		node.transport = defaultTransport()
+		node.client = &http.Client{Transport: node.transport}
+		if err := tls.apply(node); err != nil {
+			return err
+		}
+		fresh = append(fresh, node)
+	}
+	if len(fresh) == 0 {
+		return fmt.Errorf("cluster reported no nodes")
+	}
+
+	cc.mu.Lock()
+	cc.endpoints = fresh
+	cc.nextEndpoint = 0
+	cc.lastRefresh = time.Now()
+	cc.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,90 @@
+package cbmgr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackoffDuration_NeverExceedsMaxBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoffDuration(policy, attempt)
+		if d < 0 || d > policy.MaxBackoff {
+			t.Fatalf("attempt %d: backoff %s out of [0, %s]", attempt, d, policy.MaxBackoff)
+		}
+	}
+}
+
+func newTestClusterClient(t *testing.T, attempts int, urls ...string) *ClusterClient {
+	t.Helper()
+	cc, err := NewClusterClient("user", "pass", urls...)
+	if err != nil {
+		t.Fatalf("NewClusterClient: %s", err)
+	}
+	cc.SetRefreshInterval(0)
+	cc.SetRetryPolicy(attempts, time.Millisecond, 10*time.Millisecond)
+	return cc
+}
+
+func TestClusterClientDo_FailsFastOnAuthError(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	cc := newTestClusterClient(t, 3, srv.URL)
+	_, _, err := cc.Do(context.Background(), "GET", "/pools/default", nil, nil)
+	if !IsAuth(err) {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected Do to fail fast on auth error without retrying, got %d requests", requests)
+	}
+}
+
+func TestClusterClientDo_RetriesAgainstSiblingOn5xx(t *testing.T) {
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer bad.Close()
+
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer good.Close()
+
+	cc := newTestClusterClient(t, 2, bad.URL, good.URL)
+	resp, endpoint, err := cc.Do(context.Background(), "GET", "/pools/default", nil, nil)
+	if err != nil {
+		t.Fatalf("Do: %s", err)
+	}
+	defer resp.Body.Close()
+	if endpoint != good.URL {
+		t.Fatalf("expected the serving endpoint to be %s, got %s", good.URL, endpoint)
+	}
+}
+
+func TestClusterClientAddSeed_AppliesTLSSettings(t *testing.T) {
+	cc, err := NewClusterClient("user", "pass")
+	if err != nil {
+		t.Fatalf("NewClusterClient: %s", err)
+	}
+	cc.SetSkipVerify(true)
+
+	if err := cc.AddSeed("https://127.0.0.1:18091"); err != nil {
+		t.Fatalf("AddSeed: %s", err)
+	}
+
+	endpoints, _ := cc.snapshot()
+	if len(endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(endpoints))
+	}
+	if endpoints[0].tlsConfig == nil || !endpoints[0].tlsConfig.InsecureSkipVerify {
+		t.Fatalf("expected SetSkipVerify set before AddSeed to carry over to the new endpoint")
+	}
+}
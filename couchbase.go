@@ -2,17 +2,39 @@ package cbmgr
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultHTTPPort is used when the URL has no explicit port and the
+	// scheme is plain HTTP.
+	defaultHTTPPort = uint16(80)
+	// defaultHTTPSPort is the default Couchbase REST port when TLS is in
+	// use (couchbase's own default is 18091, not the generic 443).
+	defaultHTTPSPort = uint16(18091)
+
+	// defaultMaxIdleConnsPerHost keeps a modest pool of warm connections
+	// per node, larger than the net/http default of 2.
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultKeepAlive           = 30 * time.Second
 )
 
 type Couchbase struct {
@@ -21,6 +43,16 @@ type Couchbase struct {
 	Password string
 	info     *Node
 	cluster  *Cluster
+
+	transport *http.Transport
+	client    *http.Client
+	tlsConfig *tls.Config
+	certFile  string
+	keyFile   string
+
+	registerer prometheus.Registerer
+	tracer     trace.Tracer
+	metrics    *clientMetrics
 }
 
 type Node struct {
@@ -56,25 +88,128 @@ func New(rawURL string) (*Couchbase, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Couchbase{
+
+	c := &Couchbase{
 		URL: u,
-	}, nil
+	}
+	c.transport = defaultTransport()
+	c.client = &http.Client{Transport: c.transport}
+	return c, nil
 }
 
-func (c *Couchbase) Request(method, path string, body []byte, header *http.Header) (resp *http.Response, err error) {
+// defaultTransport builds the *http.Transport shared by all requests made
+// through a Couchbase client, so that connections are pooled and reused
+// instead of dialed fresh on every REST call.
+func defaultTransport() *http.Transport {
+	return &http.Transport{
+		MaxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:     defaultIdleConnTimeout,
+		DialContext: (&net.Dialer{
+			Timeout:   defaultDialTimeout,
+			KeepAlive: defaultKeepAlive,
+		}).DialContext,
+	}
+}
+
+// SetSkipVerify controls whether the client verifies the server's TLS
+// certificate. Useful for talking to clusters with self-signed certs, but
+// should not be used in production.
+func (c *Couchbase) SetSkipVerify(skip bool) {
+	c.ensureTLSConfig()
+	c.tlsConfig.InsecureSkipVerify = skip
+	c.applyTLSConfig()
+}
+
+// SetRootFile loads a PEM-encoded root CA bundle from path and uses it to
+// verify the server certificate presented by the cluster.
+func (c *Couchbase) SetRootFile(path string) error {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read root CA file %s: %s", path, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("failed to parse root CA file %s", path)
+	}
+
+	c.ensureTLSConfig()
+	c.tlsConfig.RootCAs = pool
+	c.applyTLSConfig()
+	return nil
+}
+
+// SetCertFile sets the path to the PEM-encoded client certificate used for
+// mutual TLS. The certificate is only loaded once a matching key has been
+// set via SetKeyFile.
+func (c *Couchbase) SetCertFile(path string) error {
+	c.certFile = path
+	return c.loadClientCertificate()
+}
+
+// SetKeyFile sets the path to the PEM-encoded private key used for mutual
+// TLS. The key is only loaded once a matching certificate has been set via
+// SetCertFile.
+func (c *Couchbase) SetKeyFile(path string) error {
+	c.keyFile = path
+	return c.loadClientCertificate()
+}
+
+func (c *Couchbase) loadClientCertificate() error {
+	if c.certFile == "" || c.keyFile == "" {
+		// wait for both halves of the key pair before loading
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %s", err)
+	}
+
+	c.ensureTLSConfig()
+	c.tlsConfig.Certificates = []tls.Certificate{cert}
+	c.applyTLSConfig()
+	return nil
+}
+
+func (c *Couchbase) ensureTLSConfig() {
+	if c.tlsConfig == nil {
+		c.tlsConfig = &tls.Config{}
+	}
+}
+
+func (c *Couchbase) applyTLSConfig() {
+	if c.transport == nil {
+		c.transport = defaultTransport()
+	}
+	c.transport.TLSClientConfig = c.tlsConfig
+	if c.client == nil {
+		c.client = &http.Client{}
+	}
+	c.client.Transport = c.transport
+}
+
+// RequestContext is like Request but carries ctx through to the underlying
+// HTTP call, so callers can cancel or time out a single REST call.
+func (c *Couchbase) RequestContext(ctx context.Context, method, path string, body []byte, header *http.Header) (resp *http.Response, err error) {
 
 	c.URL.User = url.UserPassword(c.Username, c.Password)
-	resp, err = c.request(method, path, bytes.NewReader(body), header)
+	resp, err = c.requestContext(ctx, method, path, bytes.NewReader(body), header)
 	if err != nil {
-		return nil, fmt.Errorf("Error while connecting with auth: %s", err)
+		return nil, &CouchbaseError{Op: method, Path: path, Err: err}
 	}
-	if resp.StatusCode == 401 {
-		return nil, fmt.Errorf("Error authenticating. Check user/password")
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, &CouchbaseError{Op: method, Path: path, StatusCode: resp.StatusCode, Err: ErrAuth}
 	}
 
 	return resp, nil
 }
 
+func (c *Couchbase) Request(method, path string, body []byte, header *http.Header) (resp *http.Response, err error) {
+	return c.RequestContext(context.Background(), method, path, body, header)
+}
+
 func strSliceContains(slice []string, item string) bool {
 	for _, elem := range slice {
 		if stripPort(item) == stripPort(elem) {
@@ -89,19 +224,30 @@ func stripPort(str string) string {
 }
 
 // rest request with url from client
-func (c *Couchbase) request(method, path string, body io.Reader, header *http.Header) (resp *http.Response, err error) {
+func (c *Couchbase) requestContext(ctx context.Context, method, path string, body io.Reader, header *http.Header) (resp *http.Response, err error) {
 	url := *c.URL
 	url.Path = path
 	c.Log().Debugf("method=%s url=%s", method, url.String())
-	return requestUrl(url.String(), method, path, body, header, 0)
+
+	ctx, span, header := c.startSpan(ctx, method, path, header)
+	defer span.End()
+
+	start := time.Now()
+	resp, err = c.requestUrlContext(ctx, url.String(), method, path, body, header, 0)
+	c.observeRequest(span, method, path, resp, err, time.Since(start))
+	return resp, err
 }
 
-// generic rest request with provided url
-func requestUrl(reqUrl, method, path string, body io.Reader, header *http.Header, timeout time.Duration) (resp *http.Response, err error) {
-	client := &http.Client{
-		Timeout: timeout,
-	}
-	req, err := http.NewRequest(method, reqUrl, body)
+func (c *Couchbase) request(method, path string, body io.Reader, header *http.Header) (resp *http.Response, err error) {
+	return c.requestContext(context.Background(), method, path, body, header)
+}
+
+// generic rest request with provided url, using the client's shared
+// transport. A non-zero timeout overrides the client's default for this
+// call only.
+func (c *Couchbase) requestUrlContext(ctx context.Context, reqUrl, method, path string, body io.Reader, header *http.Header, timeout time.Duration) (resp *http.Response, err error) {
+	client := c.httpClient(timeout)
+	req, err := http.NewRequestWithContext(ctx, method, reqUrl, body)
 	if err != nil {
 		return nil, err
 	}
@@ -111,94 +257,72 @@ func requestUrl(reqUrl, method, path string, body io.Reader, header *http.Header
 	return client.Do(req)
 }
 
-func (c *Couchbase) Form(method string, path string, data url.Values) (resp *http.Response, err error) {
+func (c *Couchbase) requestUrl(reqUrl, method, path string, body io.Reader, header *http.Header, timeout time.Duration) (resp *http.Response, err error) {
+	return c.requestUrlContext(context.Background(), reqUrl, method, path, body, header, timeout)
+}
+
+// httpClient returns the client's shared *http.Client, or a shallow copy
+// with a per-call timeout applied when timeout is non-zero.
+func (c *Couchbase) httpClient(timeout time.Duration) *http.Client {
+	if c.client == nil {
+		c.transport = defaultTransport()
+		c.client = &http.Client{Transport: c.transport}
+	}
+	if timeout == 0 {
+		return c.client
+	}
+	client := *c.client
+	client.Timeout = timeout
+	return &client
+}
+
+func (c *Couchbase) FormContext(ctx context.Context, method string, path string, data url.Values) (resp *http.Response, err error) {
 	headers := make(http.Header)
 	headers.Set("Content-Type", "application/x-www-form-urlencoded")
-	return c.Request(method, path, []byte(data.Encode()), &headers)
+	return c.RequestContext(ctx, method, path, []byte(data.Encode()), &headers)
+}
+
+func (c *Couchbase) Form(method string, path string, data url.Values) (resp *http.Response, err error) {
+	return c.FormContext(context.Background(), method, path, data)
+}
+
+func (c *Couchbase) PostFormContext(ctx context.Context, path string, data url.Values) (resp *http.Response, err error) {
+	return c.FormContext(ctx, "POST", path, data)
 }
 
 func (c *Couchbase) PostForm(path string, data url.Values) (resp *http.Response, err error) {
-	return c.Form("POST", path, data)
+	return c.PostFormContext(context.Background(), path, data)
 }
 
-func (c *Couchbase) RemoveNodes(removeNodes []string) error {
-	ejectNodes, _, _, allNodes, err := c.GetOTPNodes(removeNodes, []string{}, []string{})
+// RemoveNodesContext is a thin wrapper around StartRebalanceContext that
+// blocks until the rebalance ejecting removeNodes finishes or ctx is
+// done. Use StartRebalanceContext directly to stream progress or cancel
+// a stuck rebalance instead of blocking the caller.
+func (c *Couchbase) RemoveNodesContext(ctx context.Context, removeNodes []string) error {
+	ejectNodes, _, _, allNodes, err := c.GetOTPNodesContext(ctx, removeNodes, []string{}, []string{})
 	if err != nil {
 		return err
 	}
 
 	if len(ejectNodes) != len(removeNodes) {
-		return fmt.Errorf("Some nodes specified to be removed are not part of the cluster")
+		return &CouchbaseError{Op: "RemoveNodes", Path: "/controller/rebalance", Err: ErrNodeNotInCluster}
 	}
 
-	err = c.Rebalance(allNodes, ejectNodes)
+	h, err := c.startRebalanceContext(ctx, "RemoveNodes", allNodes, ejectNodes)
 	if err != nil {
 		return err
 	}
 
-	var minSleep = time.Second * 2
-	var sleep time.Duration = 0
-	var nodeInClusterCount = 0
-	for {
-		time.Sleep(sleep)
-
-		status, err := c.RebalanceStatus()
-		if err != nil {
-			sleep = 500 * time.Millisecond
-			c.Log().Warnf("Error while checking rebalance status: %s", err)
-			continue
-		}
-		sleep = time.Duration(int64(status.RecommendedRefreshPeriod * float64(time.Second)))
-		if sleep < minSleep {
-			sleep = minSleep
-		}
-
-		nodeInRebalance := false
-		for _, node := range ejectNodes {
-			if strSliceContains(status.Nodes, node) {
-				nodeInRebalance = true
-			}
-		}
-
-		if nodeInRebalance {
-			nodeInClusterCount = 0
-			continue
-		}
-
-		nodes, err := c.Nodes()
-		if err != nil {
-			c.Log().Warnf("Error while getting nodes: %s", err)
-			continue
-		}
-
-		nodeInCluster := false
-		for _, node := range nodes {
-			if strSliceContains(ejectNodes, node.OTPNode) {
-				nodeInCluster = true
-			}
-		}
-
-		if nodeInCluster {
-			if nodeInClusterCount > 10 {
-				// better handling would probably be to prevent further scaling down / pod termination
-				c.Log().Fatalf("rebalance finished, but node is still in the cluster. Rebalance failed")
-				break
-			}
-			nodeInClusterCount++
-			continue
-		}
-
-		c.Log().Infof("rebalance finished")
-		break
-	}
-
-	return nil
+	return h.Wait(ctx)
+}
 
+func (c *Couchbase) RemoveNodes(removeNodes []string) error {
+	return c.RemoveNodesContext(context.Background(), removeNodes)
 }
 
-func (c *Couchbase) GetOTPNodes(ejectNodes, failoverNode, reAddNode []string) (outEjectNodes, outFailoverNodes, outReAddNodes, outAllNodes []string, err error) {
+func (c *Couchbase) GetOTPNodesContext(ctx context.Context, ejectNodes, failoverNode, reAddNode []string) (outEjectNodes, outFailoverNodes, outReAddNodes, outAllNodes []string, err error) {
 
-	nodes, err := c.Nodes()
+	nodes, err := c.NodesContext(ctx)
 	if err != nil {
 		return
 	}
@@ -217,6 +341,10 @@ func (c *Couchbase) GetOTPNodes(ejectNodes, failoverNode, reAddNode []string) (o
 	return outEjectNodes, outFailoverNodes, outReAddNodes, outAllNodes, nil
 }
 
+func (c *Couchbase) GetOTPNodes(ejectNodes, failoverNode, reAddNode []string) (outEjectNodes, outFailoverNodes, outReAddNodes, outAllNodes []string, err error) {
+	return c.GetOTPNodesContext(context.Background(), ejectNodes, failoverNode, reAddNode)
+}
+
 func (c *Couchbase) CheckStatusCode(resp *http.Response, validStatusCodes []int) error {
 	validStatusCodesString := make([]string, len(validStatusCodes))
 
@@ -227,22 +355,10 @@ func (c *Couchbase) CheckStatusCode(resp *http.Response, validStatusCodes []int)
 		validStatusCodesString[i] = fmt.Sprintf("%d", statusCode)
 	}
 	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf(
-			"expected statusCode '%s', got %d: %s",
-			strings.Join(validStatusCodesString, ", "),
-			resp.StatusCode,
-			err,
-		)
-	}
+	body, _ := ioutil.ReadAll(resp.Body)
 
-	return fmt.Errorf(
-		"expected statusCode '%s', got %d: %s",
-		strings.Join(validStatusCodesString, ", "),
-		resp.StatusCode,
-		string(body),
-	)
+	reason := fmt.Errorf("expected statusCode '%s'", strings.Join(validStatusCodesString, ", "))
+	return newCouchbaseError(resp, reason, body)
 }
 
 func (c *Couchbase) Connect() error {
@@ -250,16 +366,16 @@ func (c *Couchbase) Connect() error {
 	return err
 }
 
-func (c *Couchbase) Nodes() (nodes []Node, err error) {
+func (c *Couchbase) NodesContext(ctx context.Context) (nodes []Node, err error) {
 	// connect without auth
 	c.Log().Debugf("getting node information")
-	resp, err := c.Request("GET", "/pools/default", nil, nil)
+	resp, err := c.RequestContext(ctx, "GET", "/pools/default", nil, nil)
 	if err != nil {
-		return nodes, fmt.Errorf("Error while connecting: %s", err)
+		return nodes, &CouchbaseError{Op: "Nodes", Path: "/pools/default", Err: err}
 	}
 
 	// uninitialized
-	if resp.StatusCode == 404 {
+	if resp.StatusCode == http.StatusNotFound {
 		return nodes, ErrorNodeUninitialized
 	}
 
@@ -284,6 +400,10 @@ func (c *Couchbase) Nodes() (nodes []Node, err error) {
 	return pool.Nodes, nil
 }
 
+func (c *Couchbase) Nodes() (nodes []Node, err error) {
+	return c.NodesContext(context.Background())
+}
+
 func (c *Couchbase) KnownOTPNodes() ([]string, error) {
 	otpNodes := []string{}
 	nodes, err := c.Nodes()
@@ -325,16 +445,25 @@ func (c *Couchbase) Info() (*Node, error) {
 func (c *Couchbase) Port() uint16 {
 	hostParts := strings.Split(c.URL.Host, ":")
 	if len(hostParts) < 2 {
-		return uint16(80)
+		return c.defaultPort()
 	}
 
 	port, err := strconv.ParseInt(hostParts[len(hostParts)-1], 10, 16)
 	if err != nil {
-		return uint16(80)
+		return c.defaultPort()
 	}
 	return uint16(port)
 }
 
+// defaultPort returns the port to assume when the URL carries none,
+// picking the TLS-enabled Couchbase REST port for https:// URLs.
+func (c *Couchbase) defaultPort() uint16 {
+	if c.URL.Scheme == "https" {
+		return defaultHTTPSPort
+	}
+	return defaultHTTPPort
+}
+
 func (c *Couchbase) ClusterID() (string, error) {
 	cluster, err := c.Cluster()
 	if err != nil {
@@ -343,21 +472,25 @@ func (c *Couchbase) ClusterID() (string, error) {
 	return cluster.UUID, nil
 }
 
-func (c *Couchbase) Rebalance(knownNodes, ejectedNodes []string) error {
+func (c *Couchbase) RebalanceContext(ctx context.Context, knownNodes, ejectedNodes []string) error {
 	c.Log().Debugf("rebalance nodes ejected=%+v known=%+v", ejectedNodes, knownNodes)
 	data := url.Values{}
 	data.Set("ejectedNodes", strings.Join(ejectedNodes, ","))
 	data.Set("knownNodes", strings.Join(knownNodes, ","))
-	resp, err := c.PostForm("/controller/rebalance", data)
+	resp, err := c.PostFormContext(ctx, "/controller/rebalance", data)
 	if err != nil {
 		return err
 	}
 	return c.CheckStatusCode(resp, []int{200})
 }
 
-func (c *Couchbase) Cluster() (*Cluster, error) {
+func (c *Couchbase) Rebalance(knownNodes, ejectedNodes []string) error {
+	return c.RebalanceContext(context.Background(), knownNodes, ejectedNodes)
+}
+
+func (c *Couchbase) ClusterContext(ctx context.Context) (*Cluster, error) {
 	if c.cluster == nil {
-		resp, err := c.Request("GET", "/pools", nil, nil)
+		resp, err := c.RequestContext(ctx, "GET", "/pools", nil, nil)
 		if err != nil {
 			return nil, fmt.Errorf("Error while connecting: %s", err)
 		}
@@ -386,6 +519,10 @@ func (c *Couchbase) Cluster() (*Cluster, error) {
 
 }
 
+func (c *Couchbase) Cluster() (*Cluster, error) {
+	return c.ClusterContext(context.Background())
+}
+
 func (c *Couchbase) updateMemoryQuota(key string, quota int) error {
 	c.Log().Debugf("update quota %s to %d", key, quota)
 	data := url.Values{}
@@ -401,60 +538,82 @@ func (c *Couchbase) Log() *logrus.Entry {
 	return logrus.WithField("component", "couchbase")
 }
 
-func (c *Couchbase) Ping(rawURL string) error {
-	resp, err := requestUrl(rawURL, "GET", "/", nil, nil, 3*time.Second)
+func (c *Couchbase) PingContext(ctx context.Context, rawURL string) error {
+	resp, err := c.requestUrlContext(ctx, rawURL, "GET", "/", nil, nil, 3*time.Second)
 	if err != nil {
 		return err
 	}
 	return c.CheckStatusCode(resp, []int{200})
 }
 
-// wait for node to become ready to accept requests
-func (c *Couchbase) IsReady(rawURL string, timeout time.Duration) (bool, error) {
+func (c *Couchbase) Ping(rawURL string) error {
+	return c.PingContext(context.Background(), rawURL)
+}
 
-	interval := time.Tick(1 * time.Second)
+// IsReadyContext is like IsReady but returns as soon as ctx is done,
+// instead of only ever giving up once timeout elapses.
+func (c *Couchbase) IsReadyContext(ctx context.Context, rawURL string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	// Keep trying until we're timed out or got a result or got an error
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	// Keep trying until we're timed out, cancelled, or got a result
 	for {
 		select {
-		// timed out
-		case <-time.After(timeout):
-			return false, NewErrorWaitNodeTimeout(rawURL)
-		case <-interval:
-			if err := c.Ping(rawURL); err == nil {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return false, NewErrorWaitNodeTimeout(rawURL)
+			}
+			return false, ctx.Err()
+		case <-ticker.C:
+			if err := c.PingContext(ctx, rawURL); err == nil {
 				// ok, node is ready
 				return true, nil
 			}
 		}
 	}
+}
 
-	return false, NewErrorWaitNodeUnexpected(rawURL)
+// wait for node to become ready to accept requests
+func (c *Couchbase) IsReady(rawURL string, timeout time.Duration) (bool, error) {
+	return c.IsReadyContext(context.Background(), rawURL, timeout)
 }
 
-// check wether a node is within a cluster and has healthy status
-func (c *Couchbase) Healthy(timeout time.Duration) error {
-	interval := time.Tick(1 * time.Second)
+// HealthyContext is like Healthy but returns as soon as ctx is done,
+// instead of only ever giving up once timeout elapses.
+func (c *Couchbase) HealthyContext(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	// Keep trying until we're timed out or got a result or got an error
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	// Keep trying until we're timed out, cancelled, or got a result
 	for {
 		select {
-		// timed out
-		case <-time.After(timeout):
-			return NewErrorHealthyTimedOut(c.URL.String())
-		case <-interval:
-			err := c.healthy()
-			if err == nil {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return NewErrorHealthyTimedOut(c.URL.String())
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			if err := c.healthyContext(ctx); err == nil {
 				// node has joined cluster
 				return nil
 			}
 		}
 	}
+}
 
-	return nil
+// check wether a node is within a cluster and has healthy status
+func (c *Couchbase) Healthy(timeout time.Duration) error {
+	return c.HealthyContext(context.Background(), timeout)
 }
 
-func (c *Couchbase) healthy() error {
-	nodes, err := c.Nodes()
+func (c *Couchbase) healthyContext(ctx context.Context) error {
+	nodes, err := c.NodesContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -476,15 +635,28 @@ func (c *Couchbase) healthy() error {
 	return nil
 }
 
-// Check wether bucket is ready
-func (c *Couchbase) BucketReady(name string) (bool, error) {
+// BucketReadyContext is like BucketReady but carries ctx through to the
+// underlying HTTP call.
+func (c *Couchbase) BucketReadyContext(ctx context.Context, name string) (bool, error) {
+	start := time.Now()
+	if c.metrics != nil {
+		defer func() {
+			c.metrics.bucketReadyWait.Observe(time.Since(start).Seconds())
+		}()
+	}
 
 	// get bucket info
-	resp, err := c.request("GET", "/pools/default/buckets/"+name, nil, nil)
+	resp, err := c.requestContext(ctx, "GET", "/pools/default/buckets/"+name, nil, nil)
+	if err != nil {
+		return false, err
+	}
 	defer resp.Body.Close()
 
-	if (err != nil) || (resp.StatusCode != 200) {
-		return false, err
+	if resp.StatusCode == http.StatusNotFound {
+		return false, &CouchbaseError{Op: "BucketReady", Path: "/pools/default/buckets/" + name, StatusCode: resp.StatusCode, Err: ErrBucketNotReady}
+	}
+	if resp.StatusCode != 200 {
+		return false, nil
 	}
 
 	// convert to status
@@ -508,13 +680,22 @@ func (c *Couchbase) BucketReady(name string) (bool, error) {
 	return true, nil
 }
 
-func (c *Couchbase) BucketDelete(name string) error {
+// Check wether bucket is ready
+func (c *Couchbase) BucketReady(name string) (bool, error) {
+	return c.BucketReadyContext(context.Background(), name)
+}
+
+func (c *Couchbase) BucketDeleteContext(ctx context.Context, name string) error {
 	c.Log().Debugf("delete bucket %s", name)
 	path := fmt.Sprintf("/pools/default/buckets/%s", name)
-	resp, err := c.Request("DELETE", path, nil, nil)
+	resp, err := c.RequestContext(ctx, "DELETE", path, nil, nil)
 	if err != nil {
 		return NewErrorDeleteBucket(name, err)
 	}
 
 	return c.CheckStatusCode(resp, []int{200})
 }
+
+func (c *Couchbase) BucketDelete(name string) error {
+	return c.BucketDeleteContext(context.Background(), name)
+}
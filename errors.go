@@ -0,0 +1,105 @@
+package cbmgr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors that callers can test for with errors.Is instead of
+// string-matching Error(), e.g. errors.Is(err, cbmgr.ErrAuth).
+var (
+	ErrAuth                = errors.New("authentication failed")
+	ErrNotFound            = errors.New("not found")
+	ErrRebalanceInProgress = errors.New("rebalance already in progress")
+	ErrBucketNotReady      = errors.New("bucket not ready")
+	ErrNodeNotInCluster    = errors.New("node not in cluster")
+)
+
+// CouchbaseError wraps a failure encountered while talking to a
+// Couchbase node with enough context - the operation, the REST path, and
+// the HTTP status code if there was one - for a caller to act on it
+// programmatically rather than parsing Error().
+type CouchbaseError struct {
+	Op         string
+	Path       string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *CouchbaseError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s %s: status %d: %s", e.Op, e.Path, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %s", e.Op, e.Path, e.Err)
+}
+
+func (e *CouchbaseError) Unwrap() error {
+	return e.Err
+}
+
+// IsAuth reports whether err, or any error it wraps, indicates failed
+// authentication.
+func IsAuth(err error) bool {
+	return errors.Is(err, ErrAuth)
+}
+
+// IsRetryable reports whether err, or any error it wraps, indicates a
+// transient condition a caller may reasonably retry, as opposed to one
+// requiring operator intervention such as bad credentials.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRebalanceInProgress) || errors.Is(err, ErrBucketNotReady)
+}
+
+// newCouchbaseError builds a CouchbaseError from an HTTP response whose
+// status code didn't match what the caller expected, classifying the
+// common cases into the package's sentinel errors.
+func newCouchbaseError(resp *http.Response, reason error, body []byte) *CouchbaseError {
+	var op, path string
+	if resp.Request != nil {
+		op = resp.Request.Method
+		if resp.Request.URL != nil {
+			path = resp.Request.URL.Path
+		}
+	}
+
+	err := reason
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		err = ErrAuth
+	case http.StatusNotFound:
+		err = ErrNotFound
+	}
+
+	return &CouchbaseError{
+		Op:         op,
+		Path:       path,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		Err:        err,
+	}
+}
+
+// ErrorNodeUninitialized is returned by Nodes when the target node has
+// not yet been initialized into a cluster.
+var ErrorNodeUninitialized = &CouchbaseError{Op: "Nodes", Path: "/pools/default", StatusCode: http.StatusNotFound, Err: ErrNotFound}
+
+// NewErrorWaitNodeTimeout builds the error returned by IsReady when a
+// node never becomes reachable within the caller's timeout.
+func NewErrorWaitNodeTimeout(rawURL string) error {
+	return &CouchbaseError{Op: "IsReady", Path: rawURL, Err: fmt.Errorf("timed out waiting for node to become ready")}
+}
+
+// NewErrorHealthyTimedOut builds the error returned by Healthy when a
+// node never reports healthy cluster membership within the caller's
+// timeout.
+func NewErrorHealthyTimedOut(rawURL string) error {
+	return &CouchbaseError{Op: "Healthy", Path: rawURL, Err: fmt.Errorf("timed out waiting for node to become healthy")}
+}
+
+// NewErrorDeleteBucket builds the error returned by BucketDelete when the
+// underlying REST call fails.
+func NewErrorDeleteBucket(name string, cause error) error {
+	return &CouchbaseError{Op: "BucketDelete", Path: "/pools/default/buckets/" + name, Err: cause}
+}
@@ -0,0 +1,42 @@
+package cbmgr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestIsAuth(t *testing.T) {
+	err := &CouchbaseError{Op: "GET", Path: "/pools/default", StatusCode: http.StatusUnauthorized, Err: ErrAuth}
+	if !IsAuth(err) {
+		t.Fatalf("expected IsAuth to recognize a wrapped ErrAuth")
+	}
+	if IsAuth(errors.New("boom")) {
+		t.Fatalf("IsAuth should not match an unrelated error")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rebalance in progress", &CouchbaseError{Err: ErrRebalanceInProgress}, true},
+		{"bucket not ready", &CouchbaseError{Err: ErrBucketNotReady}, true},
+		{"auth failure", &CouchbaseError{Err: ErrAuth}, false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, c := range cases {
+		if got := IsRetryable(c.err); got != c.want {
+			t.Errorf("%s: IsRetryable() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCouchbaseError_Unwrap(t *testing.T) {
+	err := &CouchbaseError{Err: ErrNotFound}
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected errors.Is to see through Unwrap to ErrNotFound")
+	}
+}
@@ -0,0 +1,136 @@
+package cbmgr
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// clientMetrics holds the Prometheus collectors registered for a
+// Couchbase client. It stays nil until SetMetricsRegisterer is called, so
+// a client with no registerer pays no instrumentation overhead.
+type clientMetrics struct {
+	requestDuration   *prometheus.HistogramVec
+	requestErrors     *prometheus.CounterVec
+	rebalanceDuration prometheus.Histogram
+	bucketReadyWait   prometheus.Histogram
+}
+
+// SetMetricsRegisterer registers Prometheus collectors for this client's
+// REST calls and rebalance/bucket-ready waits on reg:
+//
+//	cbmgr_request_duration_seconds{method,path,status}
+//	cbmgr_request_errors_total{method,path}
+//	cbmgr_rebalance_duration_seconds
+//	cbmgr_bucket_ready_wait_seconds
+//
+// A client with no registerer set collects no metrics.
+func (c *Couchbase) SetMetricsRegisterer(reg prometheus.Registerer) error {
+	m := &clientMetrics{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "cbmgr_request_duration_seconds",
+			Help: "Duration of Couchbase REST requests in seconds.",
+		}, []string{"method", "path", "status"}),
+		requestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cbmgr_request_errors_total",
+			Help: "Count of Couchbase REST requests that returned a transport error.",
+		}, []string{"method", "path"}),
+		rebalanceDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "cbmgr_rebalance_duration_seconds",
+			Help: "Duration of a rebalance triggered via StartRebalance or RemoveNodes.",
+		}),
+		bucketReadyWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "cbmgr_bucket_ready_wait_seconds",
+			Help: "Time spent per BucketReady call waiting on bucket health.",
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{
+		m.requestDuration,
+		m.requestErrors,
+		m.rebalanceDuration,
+		m.bucketReadyWait,
+	} {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	c.registerer = reg
+	c.metrics = m
+	return nil
+}
+
+// SetTracer enables OpenTelemetry tracing around each REST call and
+// emits rebalance lifecycle events as span events. A nil tracer (the
+// default) disables tracing with zero overhead.
+func (c *Couchbase) SetTracer(tracer trace.Tracer) {
+	c.tracer = tracer
+}
+
+// startSpan starts a span for a REST call when a tracer is configured,
+// injecting the resulting traceparent into header so it propagates to
+// the server. header may be nil, in which case startSpan allocates one -
+// the caller must use the returned header for the outgoing request, not
+// the one it passed in, or the traceparent never leaves the process.
+// With no tracer set it returns ctx and header unchanged and a no-op span,
+// so a span the caller already has in ctx is neither annotated nor ended.
+func (c *Couchbase) startSpan(ctx context.Context, op, path string, header *http.Header) (context.Context, trace.Span, *http.Header) {
+	if c.tracer == nil {
+		return ctx, trace.SpanFromContext(context.Background()), header
+	}
+
+	ctx, span := c.tracer.Start(ctx, op, trace.WithAttributes(
+		attribute.String("couchbase.op", op),
+		attribute.String("couchbase.path", path),
+	))
+
+	if header == nil {
+		header = &http.Header{}
+	}
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(*header))
+
+	return ctx, span, header
+}
+
+// observeRequest records a completed REST call against the configured
+// metrics and the current span, a no-op when neither is set.
+func (c *Couchbase) observeRequest(span trace.Span, method, path string, resp *http.Response, err error, elapsed time.Duration) {
+	status := "error"
+	if resp != nil {
+		status = strconv.Itoa(resp.StatusCode)
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	if c.metrics == nil {
+		return
+	}
+	path = templateRequestPath(path)
+	c.metrics.requestDuration.WithLabelValues(method, path, status).Observe(elapsed.Seconds())
+	if err != nil {
+		c.metrics.requestErrors.WithLabelValues(method, path).Inc()
+	}
+}
+
+// bucketPathPattern matches REST paths scoped to a single bucket, whose
+// name would otherwise land in a metrics label.
+var bucketPathPattern = regexp.MustCompile(`^/pools/default/buckets/[^/]+`)
+
+// templateRequestPath replaces identifiers in path with a fixed
+// placeholder before it is used as a Prometheus label value, so per-entity
+// REST calls (e.g. one path per bucket name) don't grow the
+// cbmgr_request_duration_seconds/cbmgr_request_errors_total series
+// unbounded.
+func templateRequestPath(path string) string {
+	return bucketPathPattern.ReplaceAllString(path, "/pools/default/buckets/:name")
+}
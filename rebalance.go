@@ -0,0 +1,244 @@
+package cbmgr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RebalanceProgress is a snapshot of an in-flight rebalance, as observed
+// by polling RebalanceStatus and Nodes. RebalanceStatus reports only
+// which ejected nodes are still listed as rebalancing, not how far each
+// has progressed, so Percent/PerNode are binary per node - 0 while a
+// node is still listed, 100 once it drops off - rather than a continuous
+// measure of how much work is left.
+type RebalanceProgress struct {
+	Percent                  float64
+	PerNode                  map[string]float64
+	RecommendedRefreshPeriod float64
+}
+
+// RebalanceHandle tracks a rebalance started with StartRebalance. Unlike
+// RemoveNodes, it does not block the caller: progress can be streamed,
+// waited on with a cancellable context, or the rebalance can be stopped
+// outright.
+type RebalanceHandle struct {
+	c          *Couchbase
+	ejectNodes []string
+	progress   chan RebalanceProgress
+	done       chan struct{}
+	stop       chan struct{}
+	stopOnce   sync.Once
+	err        error
+
+	span       trace.Span
+	metricsEnd func()
+}
+
+// StartRebalanceContext kicks off a rebalance moving the cluster to
+// knownNodes/ejectedNodes and returns a handle for tracking it, instead
+// of blocking until the rebalance finishes.
+func (c *Couchbase) StartRebalanceContext(ctx context.Context, knownNodes, ejectedNodes []string) (*RebalanceHandle, error) {
+	return c.startRebalanceContext(ctx, "StartRebalance", knownNodes, ejectedNodes)
+}
+
+// StartRebalance is StartRebalanceContext with context.Background().
+func (c *Couchbase) StartRebalance(knownNodes, ejectedNodes []string) (*RebalanceHandle, error) {
+	return c.StartRebalanceContext(context.Background(), knownNodes, ejectedNodes)
+}
+
+// startRebalanceContext is the shared implementation behind
+// StartRebalanceContext and RemoveNodesContext; op labels the span with
+// whichever entry point the caller actually used.
+func (c *Couchbase) startRebalanceContext(ctx context.Context, op string, knownNodes, ejectedNodes []string) (*RebalanceHandle, error) {
+	ctx, span, _ := c.startSpan(ctx, op, "/controller/rebalance", nil)
+	span.AddEvent("rebalance start")
+
+	start := time.Now()
+	metricsEnd := func() {
+		if c.metrics != nil {
+			c.metrics.rebalanceDuration.Observe(time.Since(start).Seconds())
+		}
+	}
+
+	if err := c.RebalanceContext(ctx, knownNodes, ejectedNodes); err != nil {
+		metricsEnd()
+		span.RecordError(err)
+		span.End()
+		return nil, err
+	}
+
+	h := &RebalanceHandle{
+		c:          c,
+		ejectNodes: ejectedNodes,
+		progress:   make(chan RebalanceProgress, 1),
+		done:       make(chan struct{}),
+		stop:       make(chan struct{}),
+		span:       span,
+		metricsEnd: metricsEnd,
+	}
+	go h.poll(ctx)
+	return h, nil
+}
+
+// Progress returns a channel of progress snapshots for the rebalance.
+// The channel only ever holds the most recent snapshot - a slow consumer
+// sees the latest state, not a backlog - and is closed once the
+// rebalance finishes, fails, or its context is cancelled.
+func (h *RebalanceHandle) Progress() (<-chan RebalanceProgress, error) {
+	return h.progress, nil
+}
+
+// Wait blocks until the rebalance finishes or ctx is done, returning the
+// rebalance's terminal error, if any.
+func (h *RebalanceHandle) Wait(ctx context.Context) error {
+	select {
+	case <-h.done:
+		return h.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop cancels the in-flight rebalance via /controller/stopRebalance and
+// signals poll to stop watching it, instead of leaving poll to discover
+// the stopped nodes are still in the cluster and report that as a
+// failure after ~10 more polls.
+func (h *RebalanceHandle) Stop() error {
+	resp, err := h.c.Request("POST", "/controller/stopRebalance", nil, nil)
+	if err != nil {
+		return err
+	}
+	// CheckStatusCode only closes resp.Body on the mismatch path.
+	defer resp.Body.Close()
+	if err := h.c.CheckStatusCode(resp, []int{200}); err != nil {
+		return err
+	}
+
+	h.stopOnce.Do(func() { close(h.stop) })
+	return nil
+}
+
+// poll drives the rebalance to completion, publishing progress snapshots
+// and closing done with the terminal error, if any, once it stops -
+// whether because it finished, ctx was cancelled, or the ejected nodes
+// never left the cluster.
+func (h *RebalanceHandle) poll(ctx context.Context) {
+	defer h.span.End()
+	defer h.metricsEnd()
+	defer close(h.progress)
+	defer close(h.done)
+
+	c := h.c
+	ejectNodes := h.ejectNodes
+
+	var minSleep = time.Second * 2
+	var sleep time.Duration = 0
+	var nodeInClusterCount = 0
+	for {
+		select {
+		case <-ctx.Done():
+			h.err = ctx.Err()
+			h.span.RecordError(h.err)
+			return
+		case <-h.stop:
+			h.span.AddEvent("rebalance stopped")
+			c.Log().Infof("rebalance stopped")
+			return
+		case <-time.After(sleep):
+		}
+
+		status, err := c.RebalanceStatus()
+		if err != nil {
+			sleep = 500 * time.Millisecond
+			c.Log().Warnf("Error while checking rebalance status: %s", err)
+			continue
+		}
+		sleep = time.Duration(int64(status.RecommendedRefreshPeriod * float64(time.Second)))
+		if sleep < minSleep {
+			sleep = minSleep
+		}
+
+		nodeInRebalance := false
+		perNode := make(map[string]float64, len(ejectNodes))
+		for _, node := range ejectNodes {
+			if strSliceContains(status.Nodes, node) {
+				nodeInRebalance = true
+				perNode[node] = 0
+			} else {
+				perNode[node] = 100
+			}
+		}
+
+		h.emit(RebalanceProgress{
+			Percent:                  percentDone(perNode),
+			PerNode:                  perNode,
+			RecommendedRefreshPeriod: status.RecommendedRefreshPeriod,
+		})
+		h.span.AddEvent("rebalance progress", trace.WithAttributes(
+			attribute.Float64("recommended_refresh_period_seconds", status.RecommendedRefreshPeriod),
+		))
+
+		if nodeInRebalance {
+			nodeInClusterCount = 0
+			continue
+		}
+
+		nodes, err := c.NodesContext(ctx)
+		if err != nil {
+			c.Log().Warnf("Error while getting nodes: %s", err)
+			continue
+		}
+
+		nodeInCluster := false
+		for _, node := range nodes {
+			if strSliceContains(ejectNodes, node.OTPNode) {
+				nodeInCluster = true
+			}
+		}
+
+		if nodeInCluster {
+			if nodeInClusterCount > 10 {
+				h.err = fmt.Errorf("rebalance finished, but node is still in the cluster")
+				h.span.AddEvent("rebalance end", trace.WithAttributes(attribute.Bool("failed", true)))
+				h.span.RecordError(h.err)
+				c.Log().Errorf("%s", h.err)
+				return
+			}
+			nodeInClusterCount++
+			continue
+		}
+
+		h.span.AddEvent("rebalance end", trace.WithAttributes(attribute.Bool("failed", false)))
+		c.Log().Infof("rebalance finished")
+		return
+	}
+}
+
+func (h *RebalanceHandle) emit(p RebalanceProgress) {
+	select {
+	case <-h.progress:
+	default:
+	}
+	select {
+	case h.progress <- p:
+	default:
+	}
+}
+
+// percentDone averages the binary per-node progress values described on
+// RebalanceProgress; it is not a true completion percentage.
+func percentDone(perNode map[string]float64) float64 {
+	if len(perNode) == 0 {
+		return 100
+	}
+	done := 0.0
+	for _, v := range perNode {
+		done += v
+	}
+	return done / float64(len(perNode))
+}
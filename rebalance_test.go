@@ -0,0 +1,83 @@
+package cbmgr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPercentDone(t *testing.T) {
+	cases := []struct {
+		name    string
+		perNode map[string]float64
+		want    float64
+	}{
+		{"empty", map[string]float64{}, 100},
+		{"all done", map[string]float64{"a": 100, "b": 100}, 100},
+		{"half done", map[string]float64{"a": 0, "b": 100}, 50},
+	}
+	for _, c := range cases {
+		if got := percentDone(c.perNode); got != c.want {
+			t.Errorf("%s: percentDone() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRebalanceHandle_Emit_LatestWins(t *testing.T) {
+	h := &RebalanceHandle{progress: make(chan RebalanceProgress, 1)}
+	h.emit(RebalanceProgress{Percent: 10})
+	h.emit(RebalanceProgress{Percent: 20})
+
+	select {
+	case p := <-h.progress:
+		if p.Percent != 20 {
+			t.Fatalf("expected the latest snapshot (20), got %v", p.Percent)
+		}
+	default:
+		t.Fatal("expected a progress snapshot to be available")
+	}
+
+	select {
+	case <-h.progress:
+		t.Fatal("expected only the latest snapshot to be buffered")
+	default:
+	}
+}
+
+func TestRebalanceHandle_Stop_ClosesStopChannelAndIsIdempotent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+
+	h := &RebalanceHandle{c: c, stop: make(chan struct{})}
+	if err := h.Stop(); err != nil {
+		t.Fatalf("Stop: %s", err)
+	}
+
+	select {
+	case <-h.stop:
+	default:
+		t.Fatal("expected Stop to close the stop channel")
+	}
+
+	if err := h.Stop(); err != nil {
+		t.Fatalf("second Stop should not panic on an already-closed channel: %s", err)
+	}
+}
+
+func TestRebalanceHandle_Wait_ReturnsCtxErrOnCancel(t *testing.T) {
+	h := &RebalanceHandle{done: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := h.Wait(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}